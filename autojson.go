@@ -2,8 +2,8 @@ package autojson
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"reflect"
@@ -86,8 +86,10 @@ func reflectReturns(f reflect.Type) (returnsIndex, error) {
 	return r, nil
 }
 
-// NewHandler uses reflection to generate an http.HandlerFunc from a service and method name
-func NewHandler(service interface{}, methodName string) http.HandlerFunc {
+// NewHandler uses reflection to generate an http.HandlerFunc from a service
+// and method name. Pass HandlerOptions to hook into the request/response
+// lifecycle or override the default JSON encoding.
+func NewHandler(service interface{}, methodName string, opts ...HandlerOption) http.HandlerFunc {
 	serviceVal := reflect.ValueOf(service)
 	serviceType := serviceVal.Type()
 
@@ -105,18 +107,24 @@ func NewHandler(service interface{}, methodName string) http.HandlerFunc {
 		panic(fmt.Errorf("NewHandler(%s, %#v) %v", serviceType.String(), methodName, err))
 	}
 
-	return buildHandler(in, out, serviceVal, method)
+	return buildHandler(in, out, serviceVal, method, newHandlerConfig(opts))
 }
 
-func buildHandler(in argsIndex, out returnsIndex, service reflect.Value, method reflect.Method) http.HandlerFunc {
+func buildHandler(in argsIndex, out returnsIndex, service reflect.Value, method reflect.Method, cfg *handlerConfig) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		defer r.Body.Close()
 
+		ctx := r.Context()
+		for _, before := range cfg.before {
+			ctx = before(ctx, r)
+		}
+		r = r.WithContext(ctx)
+
 		args := make([]reflect.Value, method.Type.NumIn())
 		args[0] = service
 
 		if in.ctx != -1 {
-			args[in.ctx] = reflect.ValueOf(r.Context())
+			args[in.ctx] = reflect.ValueOf(ctx)
 		}
 		if in.httpReq != -1 {
 			args[in.httpReq] = reflect.ValueOf(r)
@@ -124,15 +132,24 @@ func buildHandler(in argsIndex, out returnsIndex, service reflect.Value, method
 		if in.httpRes != -1 {
 			args[in.httpRes] = reflect.ValueOf(w)
 		}
+
+		respCodec := cfg.codecs.ResponseCodec(r)
+
 		if in.req != -1 {
 			inType := method.Type.In(in.req)
 			inValue := reflect.New(inType)
 			inInterface := inValue.Interface()
 
-			d := json.NewDecoder(r.Body)
-
-			err := d.Decode(inInterface)
-
+			var err error
+			if cfg.reqDecoder != nil {
+				err = cfg.reqDecoder(r, inInterface)
+			} else {
+				var body []byte
+				body, err = ioutil.ReadAll(r.Body)
+				if err == nil {
+					err = cfg.codecs.RequestCodec(r).Unmarshal(body, inInterface)
+				}
+			}
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusBadRequest)
 				return
@@ -167,24 +184,60 @@ func buildHandler(in argsIndex, out returnsIndex, service reflect.Value, method
 			return
 		}
 
-		// if you don't specify a http code, default to 500 or 200
+		// if you don't specify a http code, default to 500 or 200, unless
+		// the error satisfies Error, in which case we default to its
+		// HTTPStatus() instead.
 		if outCode == 0 {
 			if outErr == nil {
 				outCode = 200
+			} else if aerr, ok := outErr.(Error); ok {
+				outCode = aerr.HTTPStatus()
 			} else {
 				outCode = 500
 			}
 		}
 
+		for _, after := range cfg.after {
+			ctx = after(ctx, w)
+		}
+
+		if outErr != nil && cfg.errorEncoder != nil {
+			cfg.errorEncoder(ctx, outErr, w)
+			return
+		}
+
 		if outErr != nil {
-			outRes = &ErrorResponse{Error: outErr.Error()}
+			if aerr, ok := outErr.(Error); ok {
+				body := &ErrorBody{Code: aerr.Code(), Error: aerr.Error()}
+				if derr, ok := aerr.(DetailedError); ok {
+					body.Details = derr.Details()
+				}
+				outRes = body
+			} else {
+				outRes = &ErrorResponse{Error: outErr.Error()}
+			}
+		}
+
+		if cfg.resEncoder != nil {
+			w.Header().Set("Content-Type", respCodec.ContentType())
+			w.WriteHeader(outCode)
+			if err := cfg.resEncoder(w, outRes); err != nil {
+				log.Printf("ResponseEncoder error: %v\n", err)
+			}
+			return
+		}
+
+		_, isStreamWriter := outRes.(StreamWriter)
+		if cfg.streaming || isStreamWriter || isChan(outRes) {
+			streamResponse(w, outCode, outRes)
+			return
 		}
 
-		// If your handler returns something that cannot be marshalled
-		// to valid JSON, we're going to return an error and override
-		// any requested status code to 500.
+		// If your handler returns something that cannot be marshalled,
+		// we're going to return an error and override any requested
+		// status code to 500.
 		//
-		// Pros of encoding JSON to a buffer first:
+		// Pros of encoding to a buffer first:
 		// - We can send a correct Content-Length so net/http doesn't have
 		//   to do any games with our output
 		// - We can capture this type of error and give a nice reply instead
@@ -194,16 +247,30 @@ func buildHandler(in argsIndex, out returnsIndex, service reflect.Value, method
 		// - A huge data structure must be buffered in memory first
 		// - An object with a special encoding method could have streamed
 		//   bytes to the client.  (Super cool, but not common at all.)
-		buf, err := json.Marshal(outRes)
+		buf, err := respCodec.Marshal(outRes)
 		if err != nil {
-			// We still want this error to be JSON
-			outCode = 500
-			outRes = &ErrorResponse{Error: err.Error()}
-			buf, err = json.Marshal(outRes)
+			switch outRes.(type) {
+			case *ErrorBody, *ErrorResponse:
+				// outRes is already an error envelope; the negotiated
+				// codec just can't carry it (e.g. ProtobufCodec requires
+				// a proto.Message, and neither envelope type is one).
+				// Fall back to JSON for it without touching outCode or
+				// the envelope itself, so the error's real status and
+				// message still reach the client.
+				respCodec = JSONCodec{}
+				buf, err = respCodec.Marshal(outRes)
+			default:
+				// We still want this error encoded, in JSON, since the
+				// caller's requested codec just failed on us
+				outCode = 500
+				respCodec = JSONCodec{}
+				outRes = &ErrorResponse{Error: err.Error()}
+				buf, err = respCodec.Marshal(outRes)
+			}
 
 			if err != nil {
 				// Well, shit
-				log.Printf("Error encoding error to JSON: %v\n", err)
+				log.Printf("Error encoding error response: %v\n", err)
 				http.Error(w, err.Error(), 500)
 				return
 			}
@@ -212,7 +279,7 @@ func buildHandler(in argsIndex, out returnsIndex, service reflect.Value, method
 		}
 
 		w.Header().Set("Content-Length", strconv.Itoa(len(buf)))
-		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Type", respCodec.ContentType())
 
 		w.WriteHeader(outCode)
 
@@ -91,6 +91,19 @@ func (Service) Unencodable() Unencodable {
 func (Service) BadRequest(in string) {
 }
 
+func (Service) NotFoundTest() error {
+	return NotFound("42")
+}
+func (Service) InvalidParamsTest() error {
+	return InvalidParams([]FieldError{{Field: "name", Message: "required"}})
+}
+func (Service) UnauthorizedTest() error {
+	return Unauthorized()
+}
+func (Service) ActionNotAllowedTest() error {
+	return ActionNotAllowed("delete")
+}
+
 // This should panic because you can't deserialize one body request into multiple arguments
 func (Service) TooManyArguments(a, b string) {
 }
@@ -128,6 +141,10 @@ func TestNewHandler(t *testing.T) {
 		{"CustomResponse", "", "A plain text response", 201, ""},
 		{"Unencodable", "", "{\"error\":\"json: unsupported type: autojson.Unencodable\"}", 500, ""},
 		{"BadRequest", "yo", "invalid character 'y' looking for beginning of value\n", 400, ""},
+		{"NotFoundTest", "", "{\"code\":\"not_found\",\"error\":\"not found: 42\",\"details\":{\"id\":\"42\"}}", 404, ""},
+		{"InvalidParamsTest", "", "{\"code\":\"invalid_params\",\"error\":\"invalid params: 1 field(s)\",\"details\":{\"fields\":[{\"field\":\"name\",\"message\":\"required\"}]}}", 400, ""},
+		{"UnauthorizedTest", "", "{\"code\":\"unauthorized\",\"error\":\"unauthorized\"}", 401, ""},
+		{"ActionNotAllowedTest", "", "{\"code\":\"action_not_allowed\",\"error\":\"action not allowed: delete\",\"details\":{\"action\":\"delete\"}}", 403, ""},
 	}
 
 	var (
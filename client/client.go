@@ -0,0 +1,233 @@
+// Package client provides a runtime HTTP client for services exposed with
+// autojson.NewHandler / autojson.NewServiceHandler, without requiring code
+// generation. See cmd/autojson-gen for a generator that emits a concrete,
+// compile-time-checked client instead.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+
+	"github.com/yobert/autojson"
+)
+
+// ClientOption configures NewClient.
+type ClientOption func(*clientConfig)
+
+type clientConfig struct {
+	codec autojson.Codec
+}
+
+// Codec overrides the wire format NewClient uses to marshal requests and
+// unmarshal responses, the same way autojson.Codecs lets a server mount a
+// method with something other than JSON. The client sends and accepts
+// exactly this codec's Content-Type, so it must match one the server's
+// CodecRegistry also understands. It defaults to autojson.JSONCodec{}.
+func Codec(codec autojson.Codec) ClientOption {
+	return func(c *clientConfig) {
+		c.codec = codec
+	}
+}
+
+// NewClient fills in every exported func-typed field of the struct pointed
+// to by stub with an implementation that POSTs to baseURL+"/"+FieldName,
+// mirroring how NewHandler and NewServiceHandler dispatch on the server
+// side. There's no way to synthesize a new concrete type satisfying an
+// arbitrary interface through reflection alone, so stub is a struct of
+// function fields rather than an interface value; cmd/autojson-gen is the
+// alternative for when you want the client to actually implement the
+// server's interface type. Typical use:
+//
+//	type UserClient struct {
+//		GetUser func(ctx context.Context, id string) (*User, error)
+//	}
+//	c := client.NewClient(baseURL, &UserClient{}).(UserClient)
+//	u, err := c.GetUser(ctx, "42")
+//
+// Each field's function type is inspected the same way NewHandler inspects
+// a method: at most one context.Context argument, at most one request
+// argument (marshaled as the request body), and return values of
+// (response, error), (response), or (error) alone. If the server replies
+// with a registered autojson.Error code, the returned error is
+// unmarshalled into that registered concrete type instead of a generic
+// error.
+func NewClient(baseURL string, stub interface{}, opts ...ClientOption) interface{} {
+	stubVal := reflect.ValueOf(stub)
+	if stubVal.Kind() != reflect.Ptr || stubVal.Elem().Kind() != reflect.Struct {
+		panic(fmt.Errorf("client.NewClient: stub must be a pointer to a struct, got %s", stubVal.Type()))
+	}
+
+	cfg := clientConfig{codec: autojson.JSONCodec{}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	structVal := stubVal.Elem()
+	structType := structVal.Type()
+
+	httpClient := &http.Client{}
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() || field.Type.Kind() != reflect.Func {
+			continue
+		}
+		structVal.Field(i).Set(makeClientMethod(httpClient, baseURL+"/"+field.Name, field.Type, cfg.codec))
+	}
+
+	return structVal.Interface()
+}
+
+var (
+	ctxType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// makeClientMethod builds a reflect.MakeFunc closure matching fnType that
+// POSTs to url using codec, the same way buildHandler in the root package
+// builds a handler matching a reflected method signature.
+func makeClientMethod(httpClient *http.Client, url string, fnType reflect.Type, codec autojson.Codec) reflect.Value {
+	ctxIdx, reqIdx := -1, -1
+	for i := 0; i < fnType.NumIn(); i++ {
+		if ctxIdx == -1 && fnType.In(i) == ctxType {
+			ctxIdx = i
+			continue
+		}
+		if reqIdx == -1 {
+			reqIdx = i
+		}
+	}
+
+	resIdx, errIdx := -1, -1
+	for i := 0; i < fnType.NumOut(); i++ {
+		if errIdx == -1 && fnType.Out(i) == errType {
+			errIdx = i
+			continue
+		}
+		if resIdx == -1 {
+			resIdx = i
+		}
+	}
+
+	return reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+		ctx := context.Background()
+		if ctxIdx != -1 {
+			ctx = args[ctxIdx].Interface().(context.Context)
+		}
+
+		var body []byte
+		if reqIdx != -1 {
+			b, err := codec.Marshal(args[reqIdx].Interface())
+			if err != nil {
+				return clientResults(fnType, resIdx, errIdx, reflect.Value{}, err)
+			}
+			body = b
+		}
+
+		req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+		if err != nil {
+			return clientResults(fnType, resIdx, errIdx, reflect.Value{}, err)
+		}
+		req = req.WithContext(ctx)
+		req.Header.Set("Content-Type", codec.ContentType())
+		req.Header.Set("Accept", codec.ContentType())
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return clientResults(fnType, resIdx, errIdx, reflect.Value{}, err)
+		}
+		defer resp.Body.Close()
+
+		respBody, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return clientResults(fnType, resIdx, errIdx, reflect.Value{}, err)
+		}
+
+		if resp.StatusCode >= 400 {
+			return clientResults(fnType, resIdx, errIdx, reflect.Value{}, decodeError(respBody))
+		}
+
+		var resPtr reflect.Value
+		if resIdx != -1 {
+			resPtr = reflect.New(fnType.Out(resIdx))
+			if err := codec.Unmarshal(respBody, resPtr.Interface()); err != nil {
+				return clientResults(fnType, resIdx, errIdx, reflect.Value{}, err)
+			}
+		}
+
+		return clientResults(fnType, resIdx, errIdx, resPtr, nil)
+	})
+}
+
+// clientResults assembles the []reflect.Value a reflect.MakeFunc closure
+// must return for fnType, given the populated result pointer (or the zero
+// reflect.Value if there's no response output) and an error, which may be
+// nil.
+func clientResults(fnType reflect.Type, resIdx, errIdx int, resPtr reflect.Value, err error) []reflect.Value {
+	out := make([]reflect.Value, fnType.NumOut())
+	for i := range out {
+		out[i] = reflect.Zero(fnType.Out(i))
+	}
+	if resIdx != -1 && resPtr.IsValid() {
+		out[resIdx] = resPtr.Elem()
+	}
+	if errIdx != -1 && err != nil {
+		out[errIdx] = reflect.ValueOf(err)
+	}
+	return out
+}
+
+// errorEnvelope mirrors autojson.ErrorBody, but with Details left raw so it
+// can be re-unmarshalled into the registered concrete error type. It's
+// always decoded as JSON regardless of the negotiated Codec: ErrorBody and
+// ErrorResponse aren't proto.Message values, so when ProtobufCodec was
+// negotiated, buildHandler's can't-marshal-this fallback writes them
+// through JSONCodec instead. A codec that can marshal them writes them in
+// its own format; decodeError does not parse that format and returns the
+// raw body as the error's message.
+type errorEnvelope struct {
+	Code    string          `json:"code"`
+	Error   string          `json:"error"`
+	Details json.RawMessage `json:"details"`
+}
+
+// decodeError turns an error response body into an error. If the body has
+// a "code" registered via autojson.RegisterError, it's unmarshalled into
+// that concrete type; otherwise a plain error wrapping the message is
+// returned.
+func decodeError(body []byte) error {
+	var env errorEnvelope
+	if jsonErr := json.Unmarshal(body, &env); jsonErr != nil || env.Code == "" {
+		if jsonErr == nil && env.Error != "" {
+			return fmt.Errorf("%s", env.Error)
+		}
+		return fmt.Errorf("%s", body)
+	}
+
+	prototype, ok := autojson.LookupError(env.Code)
+	if !ok {
+		return fmt.Errorf("%s", env.Error)
+	}
+
+	protoType := reflect.TypeOf(prototype)
+	if protoType.Kind() == reflect.Ptr {
+		protoType = protoType.Elem()
+	}
+	instance := reflect.New(protoType)
+
+	if len(env.Details) > 0 {
+		if jsonErr := json.Unmarshal(env.Details, instance.Interface()); jsonErr != nil {
+			return fmt.Errorf("%s", env.Error)
+		}
+	}
+
+	if e, ok := instance.Interface().(autojson.Error); ok {
+		return e
+	}
+	return fmt.Errorf("%s", env.Error)
+}
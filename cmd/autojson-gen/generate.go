@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"strings"
+	"text/template"
+)
+
+// method describes one interface method in source-text form, good enough
+// to splice into the generated client template without needing a full
+// go/types type-check pass.
+type method struct {
+	Name string
+
+	CtxParam string // e.g. "ctx context.Context", empty if the method takes no context
+	ReqParam string // e.g. "name string", empty if the method takes no request argument
+	ReqType  string // just the type half of ReqParam, e.g. "string"
+
+	ResType string // e.g. "*User", empty if the method returns no response value
+	HasErr  bool
+}
+
+// Params renders the method's parameter list, e.g. "ctx context.Context,
+// name string".
+func (m method) Params() string {
+	switch {
+	case m.CtxParam != "" && m.ReqParam != "":
+		return m.CtxParam + ", " + m.ReqParam
+	case m.CtxParam != "":
+		return m.CtxParam
+	case m.ReqParam != "":
+		return m.ReqParam
+	default:
+		return ""
+	}
+}
+
+// Results renders the method's result list exactly as declared on the
+// source interface, e.g. "(*User, error)", "(*User)", "(error)", or "" for
+// a method with neither.
+func (m method) Results() string {
+	var parts []string
+	if m.ResType != "" {
+		parts = append(parts, m.ResType)
+	}
+	if m.HasErr {
+		parts = append(parts, "error")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// ErrReturn renders the statement used to report a transport or decode
+// failure (expr) through the method's declared return values. If the
+// source method has no error return to report it through, there's no way
+// to signal failure without silently returning a zero value as if the
+// call had succeeded, so it panics instead.
+func (m method) ErrReturn(expr string) string {
+	if !m.HasErr {
+		return fmt.Sprintf("panic(%s)", expr)
+	}
+	if m.ResType != "" {
+		return "return *new(" + m.ResType + "), " + expr
+	}
+	return "return " + expr
+}
+
+// SuccessReturn renders the statement used to return a successfully
+// decoded result (held in the local variable "out" when ResType != "").
+func (m method) SuccessReturn() string {
+	var parts []string
+	if m.ResType != "" {
+		parts = append(parts, "out")
+	}
+	if m.HasErr {
+		parts = append(parts, "nil")
+	}
+	if len(parts) == 0 {
+		return "return"
+	}
+	return "return " + strings.Join(parts, ", ")
+}
+
+// findInterface parses every .go file in dir and returns the methods of
+// the interface named typeName, along with the package name it was found
+// in.
+func findInterface(dir, typeName string) (pkgName string, methods []method, err error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, nil, 0)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for name, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gd, ok := decl.(*ast.GenDecl)
+				if !ok || gd.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gd.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok || ts.Name.Name != typeName {
+						continue
+					}
+					it, ok := ts.Type.(*ast.InterfaceType)
+					if !ok {
+						return "", nil, fmt.Errorf("%s is not an interface", typeName)
+					}
+					methods, err := methodsFromInterface(fset, it)
+					return name, methods, err
+				}
+			}
+		}
+	}
+	return "", nil, fmt.Errorf("interface %s not found in %s", typeName, dir)
+}
+
+func methodsFromInterface(fset *token.FileSet, it *ast.InterfaceType) ([]method, error) {
+	var methods []method
+
+	for _, f := range it.Methods.List {
+		ft, ok := f.Type.(*ast.FuncType)
+		if !ok || len(f.Names) == 0 {
+			// embedded interfaces aren't supported; autojson services
+			// don't use them either
+			continue
+		}
+
+		m := method{Name: f.Names[0].Name}
+
+		if ft.Params != nil {
+			for _, p := range ft.Params.List {
+				typeStr := exprString(fset, p.Type)
+				names := p.Names
+				if len(names) == 0 {
+					names = []*ast.Ident{{Name: ""}}
+				}
+				for _, n := range names {
+					if typeStr == "context.Context" {
+						m.CtxParam = fmt.Sprintf("%s %s", orDefault(n.Name, "ctx"), typeStr)
+						continue
+					}
+					if m.ReqParam == "" {
+						m.ReqType = typeStr
+						m.ReqParam = fmt.Sprintf("%s %s", orDefault(n.Name, "req"), typeStr)
+					}
+				}
+			}
+		}
+
+		if ft.Results != nil {
+			for _, r := range ft.Results.List {
+				typeStr := exprString(fset, r.Type)
+				count := len(r.Names)
+				if count == 0 {
+					count = 1
+				}
+				for i := 0; i < count; i++ {
+					if typeStr == "error" {
+						m.HasErr = true
+						continue
+					}
+					if m.ResType == "" {
+						m.ResType = typeStr
+					}
+				}
+			}
+		}
+
+		methods = append(methods, m)
+	}
+
+	return methods, nil
+}
+
+func orDefault(name, fallback string) string {
+	if name == "" {
+		return fallback
+	}
+	return name
+}
+
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, expr)
+	return buf.String()
+}
+
+const clientTemplate = `// Code generated by autojson-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"bytes"
+	{{if .UsesContext}}"context"
+	{{end}}"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+
+	"github.com/yobert/autojson"
+)
+
+// {{.Name}}Client is a generated, compile-time-checked client for
+// {{.Name}}, implementing the same method signatures by POSTing to
+// BaseURL+"/<MethodName>", the same convention autojson.NewServiceHandler
+// uses to mount them. Codec is sent as both Content-Type and Accept, so it
+// must match one the server's CodecRegistry understands too (see
+// autojson.Codecs / autojson.NewCodecRegistry).
+type {{.Name}}Client struct {
+	BaseURL string
+	HTTP    *http.Client
+	Codec   autojson.Codec
+}
+
+// New{{.Name}}Client returns a {{.Name}}Client that POSTs to baseURL using
+// autojson.JSONCodec{}; set the Codec field to talk to a service mounted
+// with a different one.
+func New{{.Name}}Client(baseURL string) *{{.Name}}Client {
+	return &{{.Name}}Client{BaseURL: baseURL, HTTP: &http.Client{}, Codec: autojson.JSONCodec{}}
+}
+
+{{range .Methods}}
+func (c *{{$.Name}}Client) {{.Name}}({{.Params}}) {{.Results}} {
+	{{if .ReqParam}}body, err := c.Codec.Marshal({{reqName .ReqParam}})
+	if err != nil {
+		{{.ErrReturn "err"}}
+	}{{else}}var body []byte{{end}}
+
+	req, err := http.NewRequest("POST", c.BaseURL+"/{{.Name}}", bytes.NewReader(body))
+	if err != nil {
+		{{.ErrReturn "err"}}
+	}
+	{{if .CtxParam}}req = req.WithContext({{ctxName .CtxParam}}){{end}}
+	req.Header.Set("Content-Type", c.Codec.ContentType())
+	req.Header.Set("Accept", c.Codec.ContentType())
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		{{.ErrReturn "err"}}
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		{{.ErrReturn "err"}}
+	}
+
+	if resp.StatusCode >= 400 {
+		{{.ErrReturn "decodeError(respBody)"}}
+	}
+
+	{{if .ResType}}var out {{.ResType}}
+	if err := c.Codec.Unmarshal(respBody, &out); err != nil {
+		{{.ErrReturn "err"}}
+	}
+	{{end}}{{.SuccessReturn}}
+}
+{{end}}
+
+// errorEnvelope mirrors autojson.ErrorBody, but with Details left raw so
+// it can be re-unmarshalled into the registered concrete error type. It's
+// always decoded as JSON regardless of the negotiated Codec: ErrorBody and
+// ErrorResponse aren't proto.Message values, so when ProtobufCodec was
+// negotiated, the server's can't-marshal-this fallback writes them through
+// JSONCodec instead. A codec that can marshal them writes them in its own
+// format; decodeError does not parse that format and returns the raw body
+// as the error's message.
+type errorEnvelope struct {
+	Code    string          ` + "`json:\"code\"`" + `
+	Error   string          ` + "`json:\"error\"`" + `
+	Details json.RawMessage ` + "`json:\"details\"`" + `
+}
+
+// decodeError turns an error response body into an error. If the body has
+// a "code" registered via autojson.RegisterError, it's unmarshalled into
+// that concrete type; otherwise a plain error wrapping the message is
+// returned.
+func decodeError(body []byte) error {
+	var env errorEnvelope
+	if jsonErr := json.Unmarshal(body, &env); jsonErr != nil || env.Code == "" {
+		if jsonErr == nil && env.Error != "" {
+			return fmt.Errorf("%s", env.Error)
+		}
+		return fmt.Errorf("%s", body)
+	}
+
+	prototype, ok := autojson.LookupError(env.Code)
+	if !ok {
+		return fmt.Errorf("%s", env.Error)
+	}
+
+	protoType := reflect.TypeOf(prototype)
+	if protoType.Kind() == reflect.Ptr {
+		protoType = protoType.Elem()
+	}
+	instance := reflect.New(protoType)
+
+	if len(env.Details) > 0 {
+		if jsonErr := json.Unmarshal(env.Details, instance.Interface()); jsonErr != nil {
+			return fmt.Errorf("%s", env.Error)
+		}
+	}
+
+	if e, ok := instance.Interface().(autojson.Error); ok {
+		return e
+	}
+	return fmt.Errorf("%s", env.Error)
+}
+`
+
+func reqName(reqParam string) string {
+	return strings.Fields(reqParam)[0]
+}
+
+func ctxName(ctxParam string) string {
+	return strings.Fields(ctxParam)[0]
+}
+
+// generate renders the client template for pkgName/ifaceName/methods and
+// gofmts the result.
+func generate(pkgName, ifaceName string, methods []method) ([]byte, error) {
+	tmpl, err := template.New("client").Funcs(template.FuncMap{
+		"reqName": reqName,
+		"ctxName": ctxName,
+	}).Parse(clientTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	usesContext := false
+	for _, m := range methods {
+		if m.CtxParam != "" {
+			usesContext = true
+			break
+		}
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, struct {
+		Package     string
+		Name        string
+		Methods     []method
+		UsesContext bool
+	}{pkgName, ifaceName, methods, usesContext})
+	if err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindInterfaceAndGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "autojson-gen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := `package sample
+
+import "context"
+
+type UserService interface {
+	GetUser(ctx context.Context, id string) (*User, error)
+	Ping() error
+	CachedUser(id string) *User
+}
+
+type User struct {
+	ID string
+}
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "sample.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pkgName, methods, err := findInterface(dir, "UserService")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkgName != "sample" {
+		t.Errorf("pkgName = %#v, want %#v", pkgName, "sample")
+	}
+	if len(methods) != 3 {
+		t.Fatalf("len(methods) = %d, want 3", len(methods))
+	}
+
+	getUser := methods[0]
+	if getUser.Name != "GetUser" || getUser.ResType != "*User" || !getUser.HasErr {
+		t.Errorf("GetUser method = %#v", getUser)
+	}
+	if getUser.CtxParam != "ctx context.Context" || getUser.ReqParam != "id string" {
+		t.Errorf("GetUser params = %#v / %#v", getUser.CtxParam, getUser.ReqParam)
+	}
+
+	ping := methods[1]
+	if ping.Name != "Ping" || ping.ResType != "" || !ping.HasErr {
+		t.Errorf("Ping method = %#v", ping)
+	}
+
+	cachedUser := methods[2]
+	if cachedUser.Name != "CachedUser" || cachedUser.ResType != "*User" || cachedUser.HasErr {
+		t.Errorf("CachedUser method = %#v", cachedUser)
+	}
+
+	out, err := generate(pkgName, "UserService", methods)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outStr := string(out)
+	for _, want := range []string{
+		"type UserServiceClient struct",
+		"func (c *UserServiceClient) GetUser(ctx context.Context, id string) (*User, error)",
+		"func (c *UserServiceClient) Ping() error",
+		"func (c *UserServiceClient) CachedUser(id string) *User",
+	} {
+		if !strings.Contains(outStr, want) {
+			t.Errorf("generated source missing %#v\n\n%s", want, outStr)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Command autojson-gen emits a concrete, compile-time-checked HTTP client
+// for a service interface, as an alternative to the runtime
+// reflect.MakeFunc proxy in autojson/client. Given an interface type, it
+// generates a struct with one real method per interface method, each
+// POSTing to BaseURL+"/<MethodName>" the same way autojson.NewHandler and
+// autojson.NewServiceHandler dispatch on the server side.
+//
+// Usage:
+//
+//	autojson-gen -type UserService -out userservice_client.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+func main() {
+	var (
+		typeName = flag.String("type", "", "name of the interface to generate a client for")
+		dir      = flag.String("dir", ".", "directory to search for the interface")
+		out      = flag.String("out", "", "output file (defaults to <type in snake_case>_client.go)")
+	)
+	flag.Parse()
+
+	if *typeName == "" {
+		fmt.Fprintln(os.Stderr, "autojson-gen: -type is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	outPath := *out
+	if outPath == "" {
+		outPath = fmt.Sprintf("%s_client.go", toSnake(*typeName))
+	}
+
+	pkgName, methods, err := findInterface(*dir, *typeName)
+	if err != nil {
+		log.Fatalf("autojson-gen: %v", err)
+	}
+
+	src, err := generate(pkgName, *typeName, methods)
+	if err != nil {
+		log.Fatalf("autojson-gen: %v", err)
+	}
+
+	if err := ioutil.WriteFile(outPath, src, 0644); err != nil {
+		log.Fatalf("autojson-gen: %v", err)
+	}
+}
+
+func toSnake(name string) string {
+	var out []rune
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				out = append(out, '_')
+			}
+			out = append(out, r-'A'+'a')
+			continue
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
@@ -0,0 +1,130 @@
+package autojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+)
+
+// Codec knows how to marshal and unmarshal request/response bodies for a
+// given wire format, and what Content-Type it produces.
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	ContentType() string
+}
+
+// CodecRegistry maps Content-Type / Accept header values to Codecs. The
+// zero value is not usable; use NewCodecRegistry, which pre-registers the
+// JSON and protobuf codecs the way DefaultCodecRegistry does.
+type CodecRegistry struct {
+	codecs   map[string]Codec
+	fallback Codec
+}
+
+// DefaultCodecRegistry is used by NewHandler when no CodecRegistry option is
+// given. It serves application/json by default and also understands
+// application/protobuf.
+var DefaultCodecRegistry = NewCodecRegistry()
+
+// NewCodecRegistry returns a CodecRegistry with the JSON codec registered
+// for "application/json" (and used as the fallback) and the protobuf codec
+// registered for "application/protobuf".
+func NewCodecRegistry() *CodecRegistry {
+	r := &CodecRegistry{
+		codecs:   map[string]Codec{},
+		fallback: JSONCodec{},
+	}
+	r.Register("application/json", JSONCodec{})
+	r.Register("application/protobuf", ProtobufCodec{})
+	return r
+}
+
+// Register associates contentType with codec.
+func (r *CodecRegistry) Register(contentType string, codec Codec) {
+	r.codecs[contentType] = codec
+}
+
+// Lookup returns the codec registered for contentType, falling back to the
+// JSON codec if contentType is empty, unrecognized, or unparsable.
+func (r *CodecRegistry) Lookup(contentType string) Codec {
+	if contentType == "" {
+		return r.fallback
+	}
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return r.fallback
+	}
+	if c, ok := r.codecs[mt]; ok {
+		return c
+	}
+	return r.fallback
+}
+
+// RequestCodec picks the decoder for r's body from its Content-Type header.
+func (r *CodecRegistry) RequestCodec(req *http.Request) Codec {
+	return r.Lookup(req.Header.Get("Content-Type"))
+}
+
+// ResponseCodec picks the encoder for the response from r's Accept header.
+func (r *CodecRegistry) ResponseCodec(req *http.Request) Codec {
+	return r.Lookup(req.Header.Get("Accept"))
+}
+
+// JSONCodec is the default Codec, backed by encoding/json. For proto.Message
+// values it marshals using jsonpb so that field names and well-known types
+// follow the protobuf JSON mapping instead of Go struct tags.
+type JSONCodec struct{}
+
+// Marshal implements Codec.
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	if m, ok := v.(proto.Message); ok {
+		s, err := (&jsonpb.Marshaler{}).MarshalToString(m)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(s), nil
+	}
+	return json.Marshal(v)
+}
+
+// Unmarshal implements Codec.
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	if m, ok := v.(proto.Message); ok {
+		return jsonpb.UnmarshalString(string(data), m)
+	}
+	return json.Unmarshal(data, v)
+}
+
+// ContentType implements Codec.
+func (JSONCodec) ContentType() string { return "application/json" }
+
+// ProtobufCodec marshals and unmarshals binary application/protobuf bodies.
+// It only works with values that implement proto.Message; it's an error to
+// use it with a plain Go struct.
+type ProtobufCodec struct{}
+
+// Marshal implements Codec.
+func (ProtobufCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("autojson: ProtobufCodec requires a proto.Message, got %T", v)
+	}
+	return proto.Marshal(m)
+}
+
+// Unmarshal implements Codec.
+func (ProtobufCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("autojson: ProtobufCodec requires a proto.Message, got %T", v)
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// ContentType implements Codec.
+func (ProtobufCodec) ContentType() string { return "application/protobuf" }
@@ -0,0 +1,76 @@
+package autojson
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type errCodecService struct{}
+
+func (errCodecService) NotFoundTest() error {
+	return NotFound("42")
+}
+
+// TestErrorEnvelopeCodecFallback covers a handler negotiated to respond in
+// protobuf: *ErrorBody never implements proto.Message, so ProtobufCodec.Marshal
+// always fails for it. buildHandler must fall back to JSON for the envelope
+// itself rather than treating that as an unrelated marshal failure and
+// discarding the error's real status and message.
+func TestErrorEnvelopeCodecFallback(t *testing.T) {
+	var svc errCodecService
+	h := NewServiceHandler(svc)
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	req, err := http.NewRequest("POST", server.URL+"/NotFoundTest", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "application/protobuf")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("StatusCode = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %#v, want %#v", ct, "application/json")
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"code":"not_found","error":"not found: 42","details":{"id":"42"}}`
+	if got := string(body); got != want {
+		t.Errorf("body = %#v, want %#v", got, want)
+	}
+}
+
+func TestCodecRegistryLookup(t *testing.T) {
+	r := NewCodecRegistry()
+
+	tests := []struct {
+		contentType string
+		want        string
+	}{
+		{"", "application/json"},
+		{"application/json", "application/json"},
+		{"application/json; charset=utf-8", "application/json"},
+		{"application/protobuf", "application/protobuf"},
+		{"text/plain", "application/json"},
+	}
+
+	for _, tt := range tests {
+		got := r.Lookup(tt.contentType).ContentType()
+		if got != tt.want {
+			t.Errorf("Lookup(%#v).ContentType() = %#v, want %#v", tt.contentType, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package autojson
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Error is implemented by error values that know how to describe themselves
+// with a machine-readable code and an HTTP status. When a method returns a
+// value satisfying Error, buildHandler encodes the response as
+// {"code":"...","error":"...","details":...} instead of the default
+// {"error":"..."} envelope, and uses HTTPStatus() for the response code
+// unless the method's explicit int return overrides it.
+type Error interface {
+	error
+	Code() string
+	HTTPStatus() int
+}
+
+// DetailedError is an optional extension of Error for errors that carry
+// structured data (e.g. which fields failed validation) alongside the code
+// and message. If the error returned from a method doesn't implement this,
+// the "details" field is simply omitted.
+type DetailedError interface {
+	Error
+	Details() interface{}
+}
+
+// ErrorBody is the JSON shape written for any error satisfying Error.
+type ErrorBody struct {
+	Code    string      `json:"code"`
+	Error   string      `json:"error"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+var errorRegistry = map[string]Error{}
+
+// RegisterError associates a code with a prototype Error value. A
+// client-side helper can call LookupError with the "code" field of an error
+// response to recover the concrete Go type it should unmarshal the
+// response's "details" field into.
+func RegisterError(code string, prototype Error) {
+	errorRegistry[code] = prototype
+}
+
+// LookupError returns the prototype registered for code, if any, and
+// whether one was found.
+func LookupError(code string) (Error, bool) {
+	e, ok := errorRegistry[code]
+	return e, ok
+}
+
+func init() {
+	RegisterError("not_found", &NotFoundError{})
+	RegisterError("invalid_params", &InvalidParamsError{})
+	RegisterError("unauthorized", &UnauthorizedError{})
+	RegisterError("action_not_allowed", &ActionNotAllowedError{})
+}
+
+// NotFoundError is returned by NotFound.
+type NotFoundError struct {
+	ID string `json:"id"`
+}
+
+func (e *NotFoundError) Error() string        { return fmt.Sprintf("not found: %s", e.ID) }
+func (e *NotFoundError) Code() string         { return "not_found" }
+func (e *NotFoundError) HTTPStatus() int      { return http.StatusNotFound }
+func (e *NotFoundError) Details() interface{} { return e }
+
+// NotFound returns an Error indicating that the resource identified by id
+// does not exist.
+func NotFound(id string) Error {
+	return &NotFoundError{ID: id}
+}
+
+// FieldError describes a single request field that failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// InvalidParamsError is returned by InvalidParams.
+type InvalidParamsError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (e *InvalidParamsError) Error() string {
+	return fmt.Sprintf("invalid params: %d field(s)", len(e.Fields))
+}
+func (e *InvalidParamsError) Code() string         { return "invalid_params" }
+func (e *InvalidParamsError) HTTPStatus() int      { return http.StatusBadRequest }
+func (e *InvalidParamsError) Details() interface{} { return e }
+
+// InvalidParams returns an Error describing which request fields failed
+// validation.
+func InvalidParams(fields []FieldError) Error {
+	return &InvalidParamsError{Fields: fields}
+}
+
+// UnauthorizedError is returned by Unauthorized.
+type UnauthorizedError struct{}
+
+func (e *UnauthorizedError) Error() string   { return "unauthorized" }
+func (e *UnauthorizedError) Code() string    { return "unauthorized" }
+func (e *UnauthorizedError) HTTPStatus() int { return http.StatusUnauthorized }
+
+// Unauthorized returns an Error indicating the caller is not authenticated.
+func Unauthorized() Error {
+	return &UnauthorizedError{}
+}
+
+// ActionNotAllowedError is returned by ActionNotAllowed.
+type ActionNotAllowedError struct {
+	Action string `json:"action"`
+}
+
+func (e *ActionNotAllowedError) Error() string {
+	return fmt.Sprintf("action not allowed: %s", e.Action)
+}
+func (e *ActionNotAllowedError) Code() string         { return "action_not_allowed" }
+func (e *ActionNotAllowedError) HTTPStatus() int      { return http.StatusForbidden }
+func (e *ActionNotAllowedError) Details() interface{} { return e }
+
+// ActionNotAllowed returns an Error indicating the caller may not perform
+// action.
+func ActionNotAllowed(action string) Error {
+	return &ActionNotAllowedError{Action: action}
+}
@@ -0,0 +1,111 @@
+package autojson
+
+import (
+	"context"
+	"net/http"
+)
+
+// HandlerOption configures a handler created by NewHandler.
+type HandlerOption func(*handlerConfig)
+
+type handlerConfig struct {
+	codecs       *CodecRegistry
+	before       []func(context.Context, *http.Request) context.Context
+	after        []func(context.Context, http.ResponseWriter) context.Context
+	errorEncoder func(context.Context, error, http.ResponseWriter)
+	reqDecoder   func(r *http.Request, v interface{}) error
+	resEncoder   func(w http.ResponseWriter, v interface{}) error
+	streaming    bool
+}
+
+func newHandlerConfig(opts []HandlerOption) *handlerConfig {
+	cfg := &handlerConfig{codecs: DefaultCodecRegistry}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// Before registers a function run before the handler's method is invoked. It
+// receives the request context and the incoming *http.Request, and returns
+// the context the method (and any later Before/After) will see. Use it for
+// things like authentication or propagating a trace span.
+func Before(f func(context.Context, *http.Request) context.Context) HandlerOption {
+	return func(c *handlerConfig) {
+		c.before = append(c.before, f)
+	}
+}
+
+// After registers a function run once the method has returned, before the
+// response is written. It's given the chance to set response headers based
+// on the (possibly Before-enriched) context.
+func After(f func(context.Context, http.ResponseWriter) context.Context) HandlerOption {
+	return func(c *handlerConfig) {
+		c.after = append(c.after, f)
+	}
+}
+
+// ErrorEncoder overrides how a returned error is written to the response,
+// replacing the default {"code":...,"error":...} / {"error":...} envelope.
+// It's responsible for writing the status code and body.
+func ErrorEncoder(f func(context.Context, error, http.ResponseWriter)) HandlerOption {
+	return func(c *handlerConfig) {
+		c.errorEncoder = f
+	}
+}
+
+// RequestDecoder overrides how the request body is decoded into the
+// method's request argument, replacing whatever codec the CodecRegistry
+// would have selected.
+func RequestDecoder(f func(r *http.Request, v interface{}) error) HandlerOption {
+	return func(c *handlerConfig) {
+		c.reqDecoder = f
+	}
+}
+
+// ResponseEncoder overrides how the response body is written, replacing the
+// Marshal of whatever codec the CodecRegistry would have selected. It gets
+// the result, or the error envelope when the method returned an error and
+// no ErrorEncoder is set. It's called after the Content-Type header (still
+// that of the selected codec) and the status code have already been
+// written, and is responsible only for the body.
+func ResponseEncoder(f func(w http.ResponseWriter, v interface{}) error) HandlerOption {
+	return func(c *handlerConfig) {
+		c.resEncoder = f
+	}
+}
+
+// Codecs overrides the CodecRegistry used for this handler. It defaults to
+// DefaultCodecRegistry.
+func Codecs(codecs *CodecRegistry) HandlerOption {
+	return func(c *handlerConfig) {
+		c.codecs = codecs
+	}
+}
+
+// Streaming opts a handler into writing its response directly to the
+// client instead of buffering it first. It's implied automatically when
+// the response type implements StreamWriter or is a non-nil channel (see
+// streaming.go); pass this explicitly to get the same direct-write
+// behavior for a plain value. Once bytes are on the wire this way, the
+// handler can no longer recover from a late marshal error by switching to
+// a 500 response.
+func Streaming() HandlerOption {
+	return func(c *handlerConfig) {
+		c.streaming = true
+	}
+}
+
+// Chain bundles several HandlerOptions into one, so cross-cutting concerns
+// (request-id, logging, metrics) can be applied to every handler in a
+// service without wrapping each returned http.HandlerFunc by hand:
+//
+//	common := autojson.Chain(autojson.Before(withRequestID), autojson.After(setServerHeader))
+//	mux.HandleFunc("/Foo", autojson.NewHandler(svc, "Foo", common))
+func Chain(opts ...HandlerOption) HandlerOption {
+	return func(c *handlerConfig) {
+		for _, opt := range opts {
+			opt(c)
+		}
+	}
+}
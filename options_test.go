@@ -0,0 +1,88 @@
+package autojson
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ctxKey string
+
+type OptionsService struct{}
+
+func (OptionsService) WhoAmI(ctx context.Context) string {
+	v, _ := ctx.Value(ctxKey("user")).(string)
+	return v
+}
+func (OptionsService) Boom() error {
+	return NotFound("7")
+}
+
+func TestHandlerOptionsBeforeAfter(t *testing.T) {
+	h := NewHandler(OptionsService{}, "WhoAmI",
+		Before(func(ctx context.Context, r *http.Request) context.Context {
+			return context.WithValue(ctx, ctxKey("user"), "ford")
+		}),
+		After(func(ctx context.Context, w http.ResponseWriter) context.Context {
+			w.Header().Set("X-User", ctx.Value(ctxKey("user")).(string))
+			return ctx
+		}),
+	)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	h(rec, req)
+
+	if got := rec.Body.String(); got != "\"ford\"" {
+		t.Errorf("body = %#v, want %#v", got, "\"ford\"")
+	}
+	if got := rec.Header().Get("X-User"); got != "ford" {
+		t.Errorf("X-User header = %#v, want %#v", got, "ford")
+	}
+}
+
+func TestHandlerOptionsErrorEncoder(t *testing.T) {
+	var gotCode string
+	h := NewHandler(OptionsService{}, "Boom", ErrorEncoder(func(ctx context.Context, err error, w http.ResponseWriter) {
+		if aerr, ok := err.(Error); ok {
+			gotCode = aerr.Code()
+		}
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	h(rec, req)
+
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusTeapot)
+	}
+	if gotCode != "not_found" {
+		t.Errorf("error code = %#v, want %#v", gotCode, "not_found")
+	}
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+	chain := Chain(
+		Before(func(ctx context.Context, r *http.Request) context.Context {
+			calls = append(calls, "before1")
+			return ctx
+		}),
+		Before(func(ctx context.Context, r *http.Request) context.Context {
+			calls = append(calls, "before2")
+			return ctx
+		}),
+	)
+
+	h := NewHandler(OptionsService{}, "WhoAmI", chain)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	h(rec, req)
+
+	if len(calls) != 2 || calls[0] != "before1" || calls[1] != "before2" {
+		t.Errorf("calls = %#v, want [before1 before2]", calls)
+	}
+}
@@ -0,0 +1,125 @@
+package autojson
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ServiceOption configures NewServiceHandler.
+type ServiceOption func(*serviceConfig)
+
+type serviceConfig struct {
+	nameFunc func(string) string
+	codecs   *CodecRegistry
+}
+
+// WithMethodName overrides how a reflected method name is turned into a URL
+// path segment. The default mounts a method unchanged, e.g. "GetUser" at
+// "/GetUser". Pass SnakeCase to mount it at "/get_user" instead.
+func WithMethodName(f func(string) string) ServiceOption {
+	return func(c *serviceConfig) {
+		c.nameFunc = f
+	}
+}
+
+// WithCodecs overrides the CodecRegistry used by every method NewServiceHandler
+// mounts. It defaults to DefaultCodecRegistry.
+func WithCodecs(codecs *CodecRegistry) ServiceOption {
+	return func(c *serviceConfig) {
+		c.codecs = codecs
+	}
+}
+
+// SnakeCase converts an exported Go method name like "GetUser" to
+// "get_user", for use with WithMethodName.
+func SnakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// ServiceHandler is returned by NewServiceHandler. It implements
+// http.Handler by dispatching on URL path to whichever reflected method it
+// matches, and exposes Routes() for introspection.
+type ServiceHandler struct {
+	mux    *http.ServeMux
+	routes []string
+}
+
+// ServeHTTP implements http.Handler.
+func (h *ServiceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// Routes returns the mounted paths, sorted, e.g. []string{"/GetUser", "/ListUsers"}.
+func (h *ServiceHandler) Routes() []string {
+	routes := make([]string, len(h.routes))
+	copy(routes, h.routes)
+	return routes
+}
+
+// NewServiceHandler reflects over every exported method of service and
+// mounts each under "/<MethodName>" (or a transformed name, see
+// WithMethodName), returning a ready-to-serve http.Handler. Unlike
+// NewHandler, which panics as soon as one bad method is requested, this
+// reflects every method up front and panics once with a combined error
+// listing all of them, so a broken method is caught at startup instead of
+// on first request.
+func NewServiceHandler(service interface{}, opts ...ServiceOption) *ServiceHandler {
+	cfg := serviceConfig{
+		nameFunc: func(name string) string { return name },
+		codecs:   DefaultCodecRegistry,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	serviceVal := reflect.ValueOf(service)
+	serviceType := serviceVal.Type()
+
+	h := &ServiceHandler{mux: http.NewServeMux()}
+	handlerCfg := newHandlerConfig(nil)
+	handlerCfg.codecs = cfg.codecs
+
+	var errs []string
+
+	for i := 0; i < serviceType.NumMethod(); i++ {
+		method := serviceType.Method(i)
+
+		in, err := reflectArgs(method.Type)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", method.Name, err))
+			continue
+		}
+		out, err := reflectReturns(method.Type)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", method.Name, err))
+			continue
+		}
+
+		path := "/" + cfg.nameFunc(method.Name)
+		h.mux.HandleFunc(path, buildHandler(in, out, serviceVal, method, handlerCfg))
+		h.routes = append(h.routes, path)
+	}
+
+	if len(errs) > 0 {
+		panic(fmt.Errorf("NewServiceHandler(%s): %s", serviceType.String(), strings.Join(errs, "; ")))
+	}
+
+	sort.Strings(h.routes)
+
+	return h
+}
@@ -0,0 +1,51 @@
+package autojson
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type GreeterService struct{}
+
+func (GreeterService) Hello(name string) string {
+	return "Hello, " + name
+}
+func (GreeterService) GoodBye(name string) string {
+	return "Bye, " + name
+}
+
+func TestNewServiceHandlerRoutes(t *testing.T) {
+	var svc GreeterService
+
+	h := NewServiceHandler(svc)
+
+	want := []string{"/GoodBye", "/Hello"}
+	if got := h.Routes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Routes() = %#v, want %#v", got, want)
+	}
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/Hello", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for missing body, got %d", resp.StatusCode)
+	}
+}
+
+func TestNewServiceHandlerSnakeCase(t *testing.T) {
+	var svc GreeterService
+
+	h := NewServiceHandler(svc, WithMethodName(SnakeCase))
+
+	want := []string{"/good_bye", "/hello"}
+	if got := h.Routes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Routes() = %#v, want %#v", got, want)
+	}
+}
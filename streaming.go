@@ -0,0 +1,86 @@
+package autojson
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+)
+
+// StreamWriter can be implemented by a response type to take full control
+// of how it's written to the client. If a method's result implements
+// StreamWriter, buildHandler calls WriteJSON directly against the
+// response, bypassing the usual buffer-then-marshal step (see buildHandler
+// in autojson.go for why that step normally exists).
+type StreamWriter interface {
+	WriteJSON(w io.Writer) error
+}
+
+// isChan reports whether v holds a non-nil channel value. A nil channel
+// (the zero value of a chan-typed return, or any other way of ending up
+// with one) is deliberately excluded: reflect.Value.Recv() on a nil
+// channel blocks forever, and we'd rather fall back to the normal marshal
+// path, which already turns an unencodable value into a 500.
+func isChan(v interface{}) bool {
+	if v == nil {
+		return false
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Chan && !rv.IsNil()
+}
+
+// streamResponse writes outRes directly to w instead of buffering it first.
+// A StreamWriter writes itself; a channel is drained and streamed out as a
+// JSON array, flushing between elements so each is delivered as it's
+// produced; anything else is written with a single json.Encoder.Encode.
+// Streaming always writes JSON regardless of the negotiated response
+// codec: StreamWriter's contract is to write JSON (see its doc comment),
+// and draining a channel through a Codec would mean holding an encoder
+// open across an arbitrary number of Marshal calls, which the Codec
+// interface isn't shaped for.
+func streamResponse(w http.ResponseWriter, outCode int, outRes interface{}) {
+	w.Header().Set("Content-Type", JSONCodec{}.ContentType())
+	w.WriteHeader(outCode)
+
+	flusher, _ := w.(http.Flusher)
+
+	if sw, ok := outRes.(StreamWriter); ok {
+		if err := sw.WriteJSON(w); err != nil {
+			log.Printf("StreamWriter error: %v\n", err)
+		}
+		return
+	}
+
+	if isChan(outRes) {
+		ch := reflect.ValueOf(outRes)
+		enc := json.NewEncoder(w)
+
+		io.WriteString(w, "[")
+		first := true
+		for {
+			v, ok := ch.Recv()
+			if !ok {
+				break
+			}
+			if !first {
+				io.WriteString(w, ",")
+			}
+			first = false
+
+			if err := enc.Encode(v.Interface()); err != nil {
+				log.Printf("Stream encode error: %v\n", err)
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		io.WriteString(w, "]")
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(outRes); err != nil {
+		log.Printf("Stream encode error: %v\n", err)
+	}
+}
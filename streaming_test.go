@@ -0,0 +1,78 @@
+package autojson
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestStreamingIgnoresNegotiatedCodec confirms streaming always writes (and
+// reports) JSON: a request negotiated for protobuf must not end up with a
+// Content-Type: application/protobuf header over a JSON body, which
+// happened when streamResponse was handed respCodec.ContentType() without
+// actually writing through that codec.
+func TestStreamingIgnoresNegotiatedCodec(t *testing.T) {
+	h := NewHandler(StreamingService{}, "Numbers")
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "application/protobuf")
+	h(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %#v, want %#v", ct, "application/json")
+	}
+
+	want := "[1\n,2\n,3\n]"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("body = %#v, want %#v", got, want)
+	}
+}
+
+type StreamingService struct{}
+
+func (StreamingService) Numbers() chan int {
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+	return ch
+}
+
+type rawJSON string
+
+func (r rawJSON) WriteJSON(w io.Writer) error {
+	_, err := io.WriteString(w, string(r))
+	return err
+}
+
+func (StreamingService) Raw() rawJSON {
+	return rawJSON(`{"raw":true}`)
+}
+
+func TestStreamingChannel(t *testing.T) {
+	h := NewHandler(StreamingService{}, "Numbers")
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	h(rec, req)
+
+	want := "[1\n,2\n,3\n]"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("body = %#v, want %#v", got, want)
+	}
+}
+
+func TestStreamingWriteJSON(t *testing.T) {
+	h := NewHandler(StreamingService{}, "Raw")
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	h(rec, req)
+
+	want := `{"raw":true}`
+	if got := rec.Body.String(); got != want {
+		t.Errorf("body = %#v, want %#v", got, want)
+	}
+}